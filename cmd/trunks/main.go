@@ -0,0 +1,67 @@
+// Command trunks runs a gRPC load test against a single target, following
+// the same -rate/-duration conventions as vegeta's HTTP attack command.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	trunks "github.com/straightdave/vegeta/lib"
+)
+
+func main() {
+	var (
+		target   = flag.String("target", "", "gRPC target: host:port, or etcd://<endpoints>/<key-prefix> with -etcd")
+		method   = flag.String("method", "", "full method name, e.g. /pkg.Service/Method")
+		isEtcd   = flag.Bool("etcd", false, "resolve target via etcd service discovery")
+		rate     = flag.Uint64("rate", 50, "requests per second")
+		duration = flag.Duration("duration", 10*time.Second, "attack duration")
+
+		insecure  = flag.Bool("insecure", false, "dial without TLS")
+		cert      = flag.String("cert", "", "client certificate file, for mTLS")
+		key       = flag.String("key", "", "client key file, for mTLS")
+		rootCerts = flag.String("root-certs", "", "CA bundle used to verify the server")
+		token     = flag.String("token", "", "bearer token sent on every RPC")
+	)
+	flag.Parse()
+
+	if *target == "" || *method == "" {
+		fmt.Fprintln(os.Stderr, "trunks: -target and -method are required")
+		os.Exit(1)
+	}
+
+	tgt := trunks.GTargeter{
+		Target:     *target,
+		IsEtcd:     *isEtcd,
+		MethodName: *method,
+		Dial: &trunks.DialOptions{
+			Insecure:      *insecure,
+			CertFile:      *cert,
+			KeyFile:       *key,
+			RootCertsFile: *rootCerts,
+			Token:         *token,
+		},
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	burner, err := trunks.NewBurner(ctx, &tgt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trunks: %v\n", err)
+		os.Exit(1)
+	}
+	defer burner.Stop()
+
+	for res := range burner.Burn(ctx, tgt, *rate, *duration) {
+		if res.Error != "" {
+			fmt.Fprintf(os.Stderr, "error: %s\n", res.Error)
+			continue
+		}
+		fmt.Printf("%s %s\n", res.Timestamp.Format(time.RFC3339), res.Latency)
+	}
+}
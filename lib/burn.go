@@ -7,9 +7,11 @@ import (
 	"sync"
 	"time"
 
+	"go.etcd.io/etcd/client/v3"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
 )
 
 type GTargeter struct {
@@ -18,65 +20,189 @@ type GTargeter struct {
 	MethodName   string
 	Requests     []*interface{}
 	ResponseType reflect.Type
-}
 
-// create an argument for gRPC invoke
-func (t *GTargeter) Request() interface{} {
-	return nil
-}
+	// Dial controls transport security and auth for the connection GenBurner
+	// creates. A nil Dial keeps the historical insecure, unauthenticated
+	// behavior.
+	Dial *DialOptions
+
+	// RequestFactory and ResponseFactory let callers register concrete
+	// proto.Message types for MethodName instead of relying on GenBurner to
+	// discover them via gRPC server reflection. ResponseType is consulted
+	// when ResponseFactory is nil, as a lighter-weight way to name the
+	// response type without writing a factory func.
+	RequestFactory  func() proto.Message
+	ResponseFactory func() proto.Message
+
+	// StreamKind selects how hit invokes MethodName. Zero value (Unary)
+	// keeps the existing Conn.Invoke behavior.
+	StreamKind StreamKind
 
-// create a response for gRPC invoke
-func (t *GTargeter) Response() interface{} {
-	return nil
+	// MessagesPerStream bounds how many requests ClientStream and
+	// BidiStream send per hit; StreamDuration additionally caps a
+	// BidiStream hit by wall-clock time. Both default to sending once.
+	// BidiStream's receive side isn't bounded by MessagesPerStream: it
+	// drains every response the server sends until the stream closes,
+	// which may be more or fewer messages than were sent.
+	MessagesPerStream int
+	StreamDuration    time.Duration
 }
 
 type Burner struct {
-	Conn    *grpc.ClientConn
-	Workers uint64
-	Ctx     context.Context
-	stopch  chan struct{}
+	Conn       *grpc.ClientConn
+	EtcdClient *clientv3.Client
+	Workers    uint64
+	Ctx        context.Context
+	stopch     chan struct{}
+	stopOnce   sync.Once
+
+	payload *payload
+
+	// endpoints holds one connection per comma-separated address in
+	// Target, for client-side failover. Left nil for etcd targets, since
+	// the etcd resolver already load-balances across a single connection.
+	endpoints []*endpoint
+	epIdx     uint64
 }
 
-// since Target could be Etcd, the connection may be in a different way
-// so Burnner (connection owner and initializer) comes from target
-func (t *GTargeter) GenBurner() (burner *Burner, err error) {
+// NewBurner dials t.Target and returns a fully-initialized Burner: stopch
+// is ready for Stop, and ctx governs the etcd health check and every hit's
+// RPC for the lifetime of the Burner. Server reflection and the failover
+// health probes use their own background contexts, and the health-watch
+// goroutine runs until Stop.
+func NewBurner(ctx context.Context, t *GTargeter) (burner *Burner, err error) {
+	var c *grpc.ClientConn
+	var etcdClient *clientv3.Client
+	var endpoints []*endpoint
+
+	dialOpts, err := t.Dial.grpcOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	if t.IsEtcd {
-		return nil, fmt.Errorf("Etcd is not supported yet")
+		c, etcdClient, err = t.dialEtcd(dialOpts)
+		if err != nil {
+			return nil, err
+		}
+		c.Connect()
+
+		// healthy check
+		grpcCheck := grpc_health_v1.NewHealthClient(c)
+		checkReq := &grpc_health_v1.HealthCheckRequest{
+			Service: "",
+		}
+
+		_, checkErr := grpcCheck.Check(ctx, checkReq)
+		if checkErr != nil {
+			c.Close()
+			if etcdClient != nil {
+				etcdClient.Close()
+			}
+			return nil, fmt.Errorf("Not Healthy")
+		}
+	} else {
+		// split on comma so a single static host and a replica list share
+		// the same dialer; failover routing kicks in once there's more
+		// than one healthy endpoint to route between.
+		endpoints, err = t.dialEndpoints(dialOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		anyHealthy := false
+		for _, e := range endpoints {
+			probeEndpoint(e)
+			anyHealthy = anyHealthy || e.isHealthy()
+		}
+		if !anyHealthy {
+			for _, e := range endpoints {
+				e.conn.Close()
+			}
+			return nil, fmt.Errorf("Not Healthy")
+		}
+
+		c = endpoints[0].conn
 	}
 
-	// directy dialing
-	c, err := grpc.Dial(t.Target, grpc.WithInsecure())
+	p, err := t.resolvePayload(c)
 	if err != nil {
+		c.Close()
+		if etcdClient != nil {
+			etcdClient.Close()
+		}
+		for _, e := range endpoints {
+			if e.conn != c {
+				e.conn.Close()
+			}
+		}
 		return nil, err
 	}
 
-	// healthy check
-	grpcCheck := grpc_health_v1.NewHealthClient(c)
-	checkReq := &grpc_health_v1.HealthCheckRequest{
-		Service: "",
+	b := &Burner{
+		Conn:       c,
+		EtcdClient: etcdClient,
+		Workers:    uint64(runtime.NumCPU()),
+		Ctx:        ctx,
+		stopch:     make(chan struct{}),
+		payload:    p,
+		endpoints:  endpoints,
 	}
 
-	_, checkErr := grpcCheck.Check(context.Background(), checkReq)
-	if checkErr != nil {
-		c.Close()
-		return nil, fmt.Errorf("Not Healthy")
+	if endpoints != nil {
+		go b.watchHealth()
 	}
 
-	return &Burner{
-		Conn:    c,
-		Workers: uint64(runtime.NumCPU()),
-		Ctx:     context.Background(),
-	}, nil
+	return b, nil
 }
 
-func (b *Burner) Burn(tgt GTargeter, rate uint64, du time.Duration) <-chan *Result {
+// GenBurner dials t.Target with a background context. Kept for callers
+// that don't need to cancel the Burner's RPCs from outside; new code
+// should prefer NewBurner.
+func (t *GTargeter) GenBurner() (burner *Burner, err error) {
+	return NewBurner(context.Background(), t)
+}
+
+// resolvePayload builds the payload used to marshal requests and unmarshal
+// responses for t.MethodName: registered factories where given, and gRPC
+// server reflection against conn to fill in whatever's missing.
+func (t *GTargeter) resolvePayload(conn *grpc.ClientConn) (*payload, error) {
+	p := &payload{requestFactory: t.RequestFactory, responseFactory: t.ResponseFactory}
+
+	if p.responseFactory == nil && t.ResponseType != nil {
+		respType := t.ResponseType
+		p.responseFactory = func() proto.Message {
+			msg, _ := reflect.New(respType).Interface().(proto.Message)
+			return msg
+		}
+	}
+
+	if p.requestFactory == nil || p.responseFactory == nil {
+		input, output, err := reflectMethod(context.Background(), conn, t.MethodName)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s via server reflection: %v", t.MethodName, err)
+		}
+		if p.requestFactory == nil {
+			p.inputDesc = input
+		}
+		if p.responseFactory == nil {
+			p.outputDesc = output
+		}
+	}
+
+	return p, nil
+}
+
+// Burn attacks at rate for du, or until ctx is canceled or Stop is called,
+// whichever comes first.
+func (b *Burner) Burn(ctx context.Context, tgt GTargeter, rate uint64, du time.Duration) <-chan *Result {
 
 	var workers sync.WaitGroup
 	results := make(chan *Result)
 	ticks := make(chan time.Time)
 	for i := uint64(0); i < b.Workers; i++ {
 		workers.Add(1)
-		go b.burn(tgt, &workers, ticks, results)
+		go b.burn(ctx, tgt, &workers, ticks, results)
 	}
 
 	go func() {
@@ -89,16 +215,22 @@ func (b *Burner) Burn(tgt GTargeter, rate uint64, du time.Duration) <-chan *Resu
 		for {
 			now, next := time.Now(), began.Add(time.Duration(done*interval))
 			time.Sleep(next.Sub(now))
+			tick := next
+			if now.After(tick) {
+				tick = now
+			}
 			select {
-			case ticks <- max(next, now):
+			case ticks <- tick:
 				if done++; done == hits {
 					return
 				}
+			case <-ctx.Done():
+				return
 			case <-b.stopch:
 				return
 			default: // all workers are blocked. start one more and try again
 				workers.Add(1)
-				go b.burn(tgt, &workers, ticks, results)
+				go b.burn(ctx, tgt, &workers, ticks, results)
 			}
 		}
 	}()
@@ -106,23 +238,52 @@ func (b *Burner) Burn(tgt GTargeter, rate uint64, du time.Duration) <-chan *Resu
 	return results
 }
 
+// Stop closes the Burner's connections and signals every in-flight worker
+// to drain, exactly once no matter how many times or how concurrently it's
+// called.
 func (b *Burner) Stop() {
-	select {
-	case <-b.stopch:
-		return
-	default:
+	b.stopOnce.Do(func() {
 		close(b.stopch)
-	}
+
+		b.Conn.Close()
+		if b.EtcdClient != nil {
+			b.EtcdClient.Close()
+		}
+		for _, e := range b.endpoints {
+			if e.conn != b.Conn {
+				e.conn.Close()
+			}
+		}
+	})
 }
 
-func (b *Burner) burn(tgt GTargeter, workers *sync.WaitGroup, ticks <-chan time.Time, results chan<- *Result) {
+func (b *Burner) burn(ctx context.Context, tgt GTargeter, workers *sync.WaitGroup, ticks <-chan time.Time, results chan<- *Result) {
 	defer workers.Done()
 	for tm := range ticks {
-		results <- b.hit(tgt, tm)
+		select {
+		case results <- b.hit(tgt, tm):
+		case <-ctx.Done():
+			return
+		case <-b.stopch:
+			return
+		}
 	}
 }
 
 func (b *Burner) hit(tgt GTargeter, tm time.Time) *Result {
+	switch tgt.StreamKind {
+	case ServerStream:
+		return b.hitServerStream(tgt, tm)
+	case ClientStream:
+		return b.hitClientStream(tgt, tm)
+	case BidiStream:
+		return b.hitBidiStream(tgt, tm)
+	default:
+		return b.hitUnary(tgt, tm)
+	}
+}
+
+func (b *Burner) hitUnary(tgt GTargeter, tm time.Time) *Result {
 	var res = Result{Timestamp: tm}
 	var err error
 
@@ -133,12 +294,26 @@ func (b *Burner) hit(tgt GTargeter, tm time.Time) *Result {
 		}
 	}()
 
-	req := tgt.Request()
-	resp := tgt.Response()
+	var conn *grpc.ClientConn
+	if conn, err = b.connFor(&res); err != nil {
+		return &res
+	}
+
+	var req, resp proto.Message
+	if req, err = b.payload.newRequest(tgt); err != nil {
+		return &res
+	}
+	if resp, err = b.payload.newResponse(); err != nil {
+		return &res
+	}
 
-	if err := b.Conn.Invoke(b.Ctx, tgt.MethodName, &req, &resp, nil); err != nil {
-		res.Error = err.Error()
+	if err = conn.Invoke(b.Ctx, tgt.MethodName, req, resp, nil); err != nil {
+		return &res
 	}
 
+	res.TTFB = time.Since(tm)
+	res.Messages = 1
+	res.BytesOut = uint64(proto.Size(req))
+	res.BytesIn = uint64(proto.Size(resp))
 	return &res
 }
@@ -0,0 +1,33 @@
+package trunks
+
+import (
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestBurnerStopIsIdempotentUnderConcurrency(t *testing.T) {
+	conn, err := grpc.NewClient("127.0.0.1:0", grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	b := &Burner{Conn: conn, stopch: make(chan struct{})}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Stop()
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-b.stopch:
+	default:
+		t.Fatal("stopch was not closed")
+	}
+}
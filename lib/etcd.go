@@ -0,0 +1,68 @@
+package trunks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/naming/resolver"
+	"google.golang.org/grpc"
+)
+
+// etcdScheme is the URI scheme recognized on GTargeter.Target when IsEtcd
+// is set: etcd://<endpoint>[,<endpoint>...]/<service-key-prefix>
+const etcdScheme = "etcd://"
+
+// parseEtcdTarget splits an etcd:// target into the client endpoints and the
+// service key prefix registered by endpoints.Manager on the server side.
+func (t *GTargeter) parseEtcdTarget() (endpoints []string, keyPrefix string, err error) {
+	if !strings.HasPrefix(t.Target, etcdScheme) {
+		return nil, "", fmt.Errorf("etcd target must look like %s<endpoints>/<key-prefix>, got %q", etcdScheme, t.Target)
+	}
+
+	rest := strings.TrimPrefix(t.Target, etcdScheme)
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, "", fmt.Errorf("etcd target must include a service key prefix, got %q", t.Target)
+	}
+
+	return strings.Split(parts[0], ","), parts[1], nil
+}
+
+// dialEtcd resolves Target against a live etcd cluster and dials the
+// discovered service via the etcd gRPC naming resolver, so the connection
+// tracks endpoints.Manager.Update calls made on the server side.
+func (t *GTargeter) dialEtcd(dialOpts []grpc.DialOption) (*grpc.ClientConn, *clientv3.Client, error) {
+	endpoints, keyPrefix, err := t.parseEtcdTarget()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	etcdClient, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("dialing etcd: %v", err)
+	}
+
+	builder, err := resolver.NewBuilder(etcdClient)
+	if err != nil {
+		etcdClient.Close()
+		return nil, nil, fmt.Errorf("building etcd resolver: %v", err)
+	}
+
+	opts := append([]grpc.DialOption{
+		grpc.WithResolvers(builder),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingPolicy":"round_robin"}`),
+	}, dialOpts...)
+
+	conn, err := grpc.NewClient("etcd:///"+keyPrefix, opts...)
+	if err != nil {
+		etcdClient.Close()
+		return nil, nil, err
+	}
+
+	return conn, etcdClient, nil
+}
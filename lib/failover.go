@@ -0,0 +1,129 @@
+package trunks
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	healthCheckInterval = 5 * time.Second
+	healthBackoffInit   = 1 * time.Second
+	healthBackoffMax    = 30 * time.Second
+)
+
+// endpoint is one backend connection in a failover pool, tracked for health
+// so hit can route around backends that stop serving.
+type endpoint struct {
+	addr string
+	conn *grpc.ClientConn
+
+	healthy int32 // atomic bool: 1 healthy, 0 not
+
+	backoff     time.Duration // current backoff, doubles on each consecutive failure up to healthBackoffMax
+	nextProbeAt time.Time     // zero while healthy; otherwise skip probing until this time
+}
+
+func (e *endpoint) isHealthy() bool {
+	return atomic.LoadInt32(&e.healthy) == 1
+}
+
+func (e *endpoint) setHealthy(ok bool) {
+	if ok {
+		atomic.StoreInt32(&e.healthy, 1)
+	} else {
+		atomic.StoreInt32(&e.healthy, 0)
+	}
+}
+
+// dialEndpoints splits a comma-separated Target into one dialed connection
+// per address, for client-side failover across static replicas.
+func (t *GTargeter) dialEndpoints(dialOpts []grpc.DialOption) ([]*endpoint, error) {
+	addrs := strings.Split(t.Target, ",")
+	endpoints := make([]*endpoint, 0, len(addrs))
+
+	for _, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		c, err := grpc.NewClient(addr, dialOpts...)
+		if err != nil {
+			for _, e := range endpoints {
+				e.conn.Close()
+			}
+			return nil, fmt.Errorf("dialing %s: %v", addr, err)
+		}
+		c.Connect()
+		endpoints = append(endpoints, &endpoint{addr: addr, conn: c})
+	}
+
+	return endpoints, nil
+}
+
+// pickEndpoint returns the next healthy endpoint, round robin, or an error
+// if every endpoint in the pool is currently down.
+func (b *Burner) pickEndpoint() (*endpoint, error) {
+	n := uint64(len(b.endpoints))
+	for i := uint64(0); i < n; i++ {
+		idx := atomic.AddUint64(&b.epIdx, 1) - 1
+		if e := b.endpoints[idx%n]; e.isHealthy() {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("no healthy endpoints among %d", n)
+}
+
+// watchHealth periodically probes every endpoint's health so hit routes
+// around down backends, until the Burner is stopped.
+func (b *Burner) watchHealth() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stopch:
+			return
+		case <-ticker.C:
+			for _, e := range b.endpoints {
+				probeEndpoint(e)
+			}
+		}
+	}
+}
+
+// probeEndpoint runs a single health check against e, honoring exponential
+// backoff once an endpoint has gone unhealthy so it isn't hammered every
+// tick while it recovers.
+func probeEndpoint(e *endpoint) {
+	if !e.isHealthy() && time.Now().Before(e.nextProbeAt) {
+		return
+	}
+
+	client := grpc_health_v1.NewHealthClient(e.conn)
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckInterval)
+	defer cancel()
+
+	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	healthy := err == nil && resp.Status == grpc_health_v1.HealthCheckResponse_SERVING
+
+	if healthy {
+		e.setHealthy(true)
+		e.backoff = 0
+		e.nextProbeAt = time.Time{}
+		return
+	}
+
+	e.setHealthy(false)
+	if e.backoff == 0 {
+		e.backoff = healthBackoffInit
+	} else if e.backoff < healthBackoffMax {
+		e.backoff *= 2
+		if e.backoff > healthBackoffMax {
+			e.backoff = healthBackoffMax
+		}
+	}
+	e.nextProbeAt = time.Now().Add(e.backoff)
+}
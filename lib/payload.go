@@ -0,0 +1,69 @@
+package trunks
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// payload is how a Burner turns a GTargeter.Requests template into a wire
+// request and builds a response to decode into, whether the caller
+// registered concrete proto.Message factories or left MethodName's types
+// to be discovered via server reflection.
+type payload struct {
+	requestFactory  func() proto.Message
+	responseFactory func() proto.Message
+
+	// inputDesc/outputDesc are set instead of the factories above when the
+	// method's messages were found through server reflection.
+	inputDesc  protoreflect.MessageDescriptor
+	outputDesc protoreflect.MessageDescriptor
+
+	// reqIdx cycles GTargeter.Requests round-robin across workers.
+	reqIdx uint64
+}
+
+// newRequest builds the next request message, filling it from the next
+// template in tgt.Requests (round-robin) when one is supplied.
+func (p *payload) newRequest(tgt GTargeter) (proto.Message, error) {
+	msg, err := p.newMessage(p.requestFactory, p.inputDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tgt.Requests) == 0 {
+		return msg, nil
+	}
+
+	idx := atomic.AddUint64(&p.reqIdx, 1) - 1
+	tmpl := tgt.Requests[idx%uint64(len(tgt.Requests))]
+	raw, err := json.Marshal(*tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request template: %v", err)
+	}
+	if err := protojson.Unmarshal(raw, msg); err != nil {
+		return nil, fmt.Errorf("unmarshaling request template into %s: %v", msg.ProtoReflect().Descriptor().FullName(), err)
+	}
+	return msg, nil
+}
+
+// newResponse builds an empty response message for Invoke to decode into.
+func (p *payload) newResponse() (proto.Message, error) {
+	return p.newMessage(p.responseFactory, p.outputDesc)
+}
+
+func (p *payload) newMessage(factory func() proto.Message, desc protoreflect.MessageDescriptor) (proto.Message, error) {
+	switch {
+	case factory != nil:
+		return factory(), nil
+	case desc != nil:
+		return dynamicpb.NewMessage(desc), nil
+	default:
+		return nil, fmt.Errorf("no registered factory or reflected descriptor for method")
+	}
+}
@@ -0,0 +1,103 @@
+package trunks
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// splitFullMethod turns a gRPC full method path ("/pkg.Service/Method")
+// into the service's fully-qualified symbol name and the bare method name.
+func splitFullMethod(fullMethod string) (service, method string, err error) {
+	trimmed := strings.TrimPrefix(fullMethod, "/")
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 {
+		return "", "", fmt.Errorf("malformed method name %q", fullMethod)
+	}
+	return trimmed[:idx], trimmed[idx+1:], nil
+}
+
+// reflectMethod uses gRPC server reflection to discover the input/output
+// message descriptors of fullMethod (e.g. "/pkg.Service/Method") on conn,
+// so GenBurner can build requests without the caller registering types.
+func reflectMethod(ctx context.Context, conn *grpc.ClientConn, fullMethod string) (input, output protoreflect.MessageDescriptor, err error) {
+	serviceName, methodName, err := splitFullMethod(fullMethod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := grpc_reflection_v1alpha.NewServerReflectionClient(conn)
+	stream, err := client.ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening reflection stream: %v", err)
+	}
+	defer stream.CloseSend()
+
+	if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: serviceName,
+		},
+	}); err != nil {
+		return nil, nil, fmt.Errorf("requesting descriptor for %s: %v", serviceName, err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("receiving descriptor for %s: %v", serviceName, err)
+	}
+
+	fdResp, ok := resp.MessageResponse.(*grpc_reflection_v1alpha.ServerReflectionResponse_FileDescriptorResponse)
+	if !ok {
+		return nil, nil, fmt.Errorf("server reflection did not return a file descriptor for %s", serviceName)
+	}
+
+	files, err := buildFileRegistry(fdResp.FileDescriptorResponse.FileDescriptorProto)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svcDesc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, nil, fmt.Errorf("service %s not found in reflected descriptors: %v", serviceName, err)
+	}
+	service, ok := svcDesc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+
+	methodDesc := service.Methods().ByName(protoreflect.Name(methodName))
+	if methodDesc == nil {
+		return nil, nil, fmt.Errorf("method %s not found on service %s", methodName, serviceName)
+	}
+
+	return methodDesc.Input(), methodDesc.Output(), nil
+}
+
+// buildFileRegistry parses the raw FileDescriptorProto bytes returned by
+// server reflection (dependencies first) into a queryable file registry.
+func buildFileRegistry(raw [][]byte) (*protoregistry.Files, error) {
+	files := &protoregistry.Files{}
+	for _, b := range raw {
+		fdProto := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(b, fdProto); err != nil {
+			return nil, fmt.Errorf("unmarshaling file descriptor: %v", err)
+		}
+
+		fd, err := protodesc.NewFile(fdProto, files)
+		if err != nil {
+			return nil, fmt.Errorf("building file descriptor %s: %v", fdProto.GetName(), err)
+		}
+		if err := files.RegisterFile(fd); err != nil {
+			return nil, fmt.Errorf("registering file descriptor %s: %v", fdProto.GetName(), err)
+		}
+	}
+	return files, nil
+}
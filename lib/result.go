@@ -0,0 +1,34 @@
+package trunks
+
+import "time"
+
+// Result holds the outcome of a single hit against a target.
+type Result struct {
+	Timestamp time.Time
+	Latency   time.Duration
+	Error     string
+
+	// Endpoint is the backend address this hit was routed to. Set for
+	// every hit against a non-etcd Target, including a single address,
+	// since dialEndpoints always dials through the failover pool. Empty
+	// for etcd-discovered connections, which resolve and balance inside
+	// a single grpc.ClientConn.
+	Endpoint string
+
+	// BytesOut and BytesIn are the total marshaled sizes, in bytes, of all
+	// messages sent and received for this hit: one message each way for a
+	// Unary hit, many for streaming.
+	BytesOut uint64
+	BytesIn  uint64
+
+	// Messages counts the messages exchanged for this hit: 1 for Unary,
+	// the number of requests sent for ClientStream (GTargeter.
+	// MessagesPerStream), and the number of server messages received for
+	// ServerStream and BidiStream.
+	Messages uint64
+
+	// TTFB is the time from the hit starting to the first response
+	// message arriving. For a Unary hit it is taken when Invoke returns,
+	// just before Latency, and stays zero if the hit fails.
+	TTFB time.Duration
+}
@@ -0,0 +1,283 @@
+package trunks
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamKind selects how hit invokes MethodName.
+type StreamKind int
+
+const (
+	Unary StreamKind = iota
+	ServerStream
+	ClientStream
+	BidiStream
+)
+
+// connFor picks the connection a hit should use, recording which endpoint
+// it landed on when client-side failover is active.
+func (b *Burner) connFor(res *Result) (*grpc.ClientConn, error) {
+	if b.endpoints == nil {
+		return b.Conn, nil
+	}
+	ep, err := b.pickEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	res.Endpoint = ep.addr
+	return ep.conn, nil
+}
+
+func (b *Burner) hitServerStream(tgt GTargeter, tm time.Time) *Result {
+	var res = Result{Timestamp: tm}
+	var err error
+
+	defer func() {
+		res.Latency = time.Since(tm)
+		if err != nil {
+			res.Error = err.Error()
+		}
+	}()
+
+	var conn *grpc.ClientConn
+	if conn, err = b.connFor(&res); err != nil {
+		return &res
+	}
+
+	var req proto.Message
+	if req, err = b.payload.newRequest(tgt); err != nil {
+		return &res
+	}
+
+	var stream grpc.ClientStream
+	if stream, err = conn.NewStream(b.Ctx, &grpc.StreamDesc{ServerStreams: true}, tgt.MethodName); err != nil {
+		return &res
+	}
+
+	if err = stream.SendMsg(req); err != nil {
+		return &res
+	}
+	if err = stream.CloseSend(); err != nil {
+		return &res
+	}
+	res.BytesOut = uint64(proto.Size(req))
+
+	first := true
+	for {
+		var resp proto.Message
+		if resp, err = b.payload.newResponse(); err != nil {
+			return &res
+		}
+
+		recvErr := stream.RecvMsg(resp)
+		if recvErr == io.EOF {
+			err = nil
+			return &res
+		}
+		if recvErr != nil {
+			err = recvErr
+			return &res
+		}
+
+		if first {
+			res.TTFB = time.Since(tm)
+			first = false
+		}
+		res.Messages++
+		res.BytesIn += uint64(proto.Size(resp))
+	}
+}
+
+func (b *Burner) hitClientStream(tgt GTargeter, tm time.Time) *Result {
+	var res = Result{Timestamp: tm}
+	var err error
+
+	defer func() {
+		res.Latency = time.Since(tm)
+		if err != nil {
+			res.Error = err.Error()
+		}
+	}()
+
+	var conn *grpc.ClientConn
+	if conn, err = b.connFor(&res); err != nil {
+		return &res
+	}
+
+	var stream grpc.ClientStream
+	if stream, err = conn.NewStream(b.Ctx, &grpc.StreamDesc{ClientStreams: true}, tgt.MethodName); err != nil {
+		return &res
+	}
+
+	n := tgt.MessagesPerStream
+	if n <= 0 {
+		n = 1
+	}
+
+	for i := 0; i < n; i++ {
+		var req proto.Message
+		if req, err = b.payload.newRequest(tgt); err != nil {
+			return &res
+		}
+		if err = stream.SendMsg(req); err != nil {
+			return &res
+		}
+		res.BytesOut += uint64(proto.Size(req))
+		res.Messages++
+	}
+
+	if err = stream.CloseSend(); err != nil {
+		return &res
+	}
+
+	var resp proto.Message
+	if resp, err = b.payload.newResponse(); err != nil {
+		return &res
+	}
+	if err = stream.RecvMsg(resp); err != nil {
+		return &res
+	}
+
+	res.TTFB = time.Since(tm)
+	res.BytesIn = uint64(proto.Size(resp))
+	return &res
+}
+
+func (b *Burner) hitBidiStream(tgt GTargeter, tm time.Time) *Result {
+	var res = Result{Timestamp: tm}
+	var err error
+
+	defer func() {
+		res.Latency = time.Since(tm)
+		if err != nil {
+			res.Error = err.Error()
+		}
+	}()
+
+	var conn *grpc.ClientConn
+	if conn, err = b.connFor(&res); err != nil {
+		return &res
+	}
+
+	n := tgt.MessagesPerStream
+	if n <= 0 {
+		n = 1
+	}
+
+	// streamCtx is deadlined by StreamDuration when set, bounding how
+	// long the pipelined reader below may block waiting on responses;
+	// it's also canceled outright if the sender errors out before
+	// sending anything. Without StreamDuration, a stalled server can
+	// still block the reader for as long as b.Ctx stays alive.
+	var deadline time.Time
+	streamCtx, cancelStream := context.WithCancel(b.Ctx)
+	defer cancelStream()
+	if tgt.StreamDuration > 0 {
+		deadline = tm.Add(tgt.StreamDuration)
+		var cancelDeadline context.CancelFunc
+		streamCtx, cancelDeadline = context.WithDeadline(streamCtx, deadline)
+		defer cancelDeadline()
+	}
+
+	var stream grpc.ClientStream
+	if stream, err = conn.NewStream(streamCtx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, tgt.MethodName); err != nil {
+		return &res
+	}
+
+	// Send and receive on separate goroutines so the hit actually
+	// pipelines: the writer races ahead sending up to n requests (or
+	// until StreamDuration elapses) while the reader drains responses
+	// as they arrive, instead of blocking on each response in turn.
+	var wg sync.WaitGroup
+	var sendErr, recvErr error
+	var sentAny bool
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Always close the send side, even on error. If nothing was
+		// ever sent, also cancel streamCtx: the receiver has no
+		// response to wait for, so don't leave it blocked in RecvMsg.
+		defer func() {
+			if closeErr := stream.CloseSend(); closeErr != nil && sendErr == nil {
+				sendErr = closeErr
+			}
+			if sendErr != nil && !sentAny {
+				cancelStream()
+			}
+		}()
+
+		for i := 0; i < n; i++ {
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				break
+			}
+
+			var req proto.Message
+			if req, sendErr = b.payload.newRequest(tgt); sendErr != nil {
+				return
+			}
+			if sendErr = stream.SendMsg(req); sendErr != nil {
+				return
+			}
+			sentAny = true
+			res.BytesOut += uint64(proto.Size(req))
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Drain responses until the server closes the stream, same as
+		// hitServerStream: a bidi server isn't required to answer 1:1
+		// with requests, so this isn't bounded by n. StreamDuration (via
+		// streamCtx above) is the caller's knob for capping how long
+		// that drain may take.
+		first := true
+		for {
+			var resp proto.Message
+			if resp, recvErr = b.payload.newResponse(); recvErr != nil {
+				return
+			}
+
+			e := stream.RecvMsg(resp)
+			if e == io.EOF {
+				recvErr = nil
+				return
+			}
+			if e != nil {
+				recvErr = e
+				return
+			}
+
+			if first {
+				res.TTFB = time.Since(tm)
+				first = false
+			}
+			res.Messages++
+			res.BytesIn += uint64(proto.Size(resp))
+		}
+	}()
+
+	wg.Wait()
+
+	// recvErr carries the authoritative RPC status when the stream
+	// fails mid-flight (e.g. a server-side error code); a concurrent
+	// SendMsg/CloseSend on the same broken stream usually just surfaces
+	// an opaque transport error, so prefer recvErr when both are set.
+	// Exception: if nothing was ever sent, recvErr is just the
+	// cancellation triggered above, and sendErr is the real cause.
+	if !sentAny && sendErr != nil {
+		err = sendErr
+	} else if recvErr != nil {
+		err = recvErr
+	} else {
+		err = sendErr
+	}
+	return &res
+}